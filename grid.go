@@ -0,0 +1,212 @@
+package main
+
+import (
+	"github.com/RoaringBitmap/roaring"
+)
+
+// TILE_SIZE is the side length of the bitmap tiles Grid partitions the
+// infinite plane into. Tile coordinates can be negative (ordinary ints),
+// so only the 0..TILE_SIZE*TILE_SIZE-1 in-tile index needs to fit in the
+// bitmap's uint32 domain.
+const TILE_SIZE = 16
+
+type tileKey struct {
+	tx int
+	ty int
+}
+
+// Grid is a sparse alive/dead cell set backed by per-tile roaring
+// bitmaps instead of a map[string]*Cell. Membership tests and neighbor
+// counts are bitmap lookups rather than map lookups keyed by formatted
+// coordinate strings.
+type Grid struct {
+	tiles map[tileKey]*roaring.Bitmap
+}
+
+func NewGrid() *Grid {
+	return &Grid{tiles: make(map[tileKey]*roaring.Bitmap)}
+}
+
+// floorDiv is integer division that rounds toward negative infinity, so
+// tile coordinates stay contiguous across the x/y == 0 boundary (Go's
+// native / truncates toward zero).
+func floorDiv(a int, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func tileAndLocal(v int) (int, int) {
+	tile := floorDiv(v, TILE_SIZE)
+	return tile, v - tile*TILE_SIZE
+}
+
+func (g *Grid) Set(x int, y int) {
+	tx, lx := tileAndLocal(x)
+	ty, ly := tileAndLocal(y)
+
+	key := tileKey{tx, ty}
+	bm, ok := g.tiles[key]
+	if !ok {
+		bm = roaring.New()
+		g.tiles[key] = bm
+	}
+
+	bm.Add(uint32(ly*TILE_SIZE + lx))
+}
+
+func (g *Grid) Clear(x int, y int) {
+	tx, lx := tileAndLocal(x)
+	ty, ly := tileAndLocal(y)
+
+	key := tileKey{tx, ty}
+	bm, ok := g.tiles[key]
+	if !ok {
+		return
+	}
+
+	bm.Remove(uint32(ly*TILE_SIZE + lx))
+	if bm.IsEmpty() {
+		delete(g.tiles, key)
+	}
+}
+
+func (g *Grid) Contains(x int, y int) bool {
+	tx, lx := tileAndLocal(x)
+	ty, ly := tileAndLocal(y)
+
+	bm, ok := g.tiles[tileKey{tx, ty}]
+	if !ok {
+		return false
+	}
+
+	return bm.Contains(uint32(ly*TILE_SIZE + lx))
+}
+
+func (g *Grid) Len() int {
+	total := 0
+	for _, bm := range g.tiles {
+		total += int(bm.GetCardinality())
+	}
+	return total
+}
+
+// Each visits the (x, y) of every live cell in the grid.
+func (g *Grid) Each(fn func(x int, y int)) {
+	for key, bm := range g.tiles {
+		it := bm.Iterator()
+		for it.HasNext() {
+			idx := int(it.Next())
+			fn(key.tx*TILE_SIZE+idx%TILE_SIZE, key.ty*TILE_SIZE+idx/TILE_SIZE)
+		}
+	}
+}
+
+// neighborCount is the bitmap-backed equivalent of getCellNeighborCount:
+// eight membership tests instead of eight string-keyed map lookups.
+func (g *Grid) neighborCount(x int, y int) int {
+	count := 0
+
+	for i := -1; i <= 1; i++ {
+		for j := -1; j <= 1; j++ {
+			if i == 0 && j == 0 {
+				continue
+			}
+			if g.Contains(x+i, y+j) {
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+func gridFromMap(cellMap map[string]*Cell) *Grid {
+	grid := NewGrid()
+	for _, cell := range cellMap {
+		grid.Set(cell.x, cell.y)
+	}
+	return grid
+}
+
+func (g *Grid) ToMap() map[string]*Cell {
+	cells := make(map[string]*Cell, g.Len())
+	g.Each(func(x int, y int) {
+		cells[getCellKey(x, y)] = &Cell{x: x, y: y}
+	})
+	return cells
+}
+
+// stepGrid computes the next generation of grid under rule. Each live
+// cell's survival is one neighborCount bitmap lookup, and each dead
+// neighbor of a live cell is considered for birth exactly once via
+// visited, mirroring reviveCells' dedup against the map-based backend.
+//
+// This is a direct per-cell port of the map-based algorithm onto Grid;
+// reformulating revival as a whole-tile shift/OR/popcount operation
+// would be a further optimization beyond visiting each border cell
+// individually, but isn't implemented here.
+func stepGrid(grid *Grid, rule Rule) *Grid {
+	next := NewGrid()
+	visited := make(map[tileKey]map[int]bool)
+
+	seen := func(x int, y int) bool {
+		tx, lx := tileAndLocal(x)
+		ty, ly := tileAndLocal(y)
+		key := tileKey{tx, ty}
+
+		local, ok := visited[key]
+		if !ok {
+			local = make(map[int]bool)
+			visited[key] = local
+		}
+
+		idx := ly*TILE_SIZE + lx
+		if local[idx] {
+			return true
+		}
+		local[idx] = true
+		return false
+	}
+
+	grid.Each(func(x int, y int) {
+		if rule.Survive[grid.neighborCount(x, y)] {
+			next.Set(x, y)
+		}
+
+		for i := -1; i <= 1; i++ {
+			for j := -1; j <= 1; j++ {
+				if i == 0 && j == 0 {
+					continue
+				}
+
+				nx, ny := x+i, y+j
+				if grid.Contains(nx, ny) || seen(nx, ny) {
+					continue
+				}
+
+				if rule.Birth[grid.neighborCount(nx, ny)] {
+					next.Set(nx, ny)
+				}
+			}
+		}
+	})
+
+	return next
+}
+
+// GridSimulator is a Simulator backed by Grid's roaring-bitmap tiles
+// instead of NaiveSimulator's map[string]*Cell diffing.
+type GridSimulator struct{}
+
+func (GridSimulator) Step(cellMap map[string]*Cell, n int, rule Rule) map[string]*Cell {
+	grid := gridFromMap(cellMap)
+
+	for i := 0; i < n; i++ {
+		grid = stepGrid(grid, rule)
+	}
+
+	return grid.ToMap()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// generateSoup deterministically scatters n live cells across a
+// width x width square centered on the origin.
+func generateSoup(n int, width int) map[string]*Cell {
+	r := rand.New(rand.NewSource(1))
+	cells := make(map[string]*Cell, n)
+
+	for len(cells) < n {
+		x := r.Intn(width) - width/2
+		y := r.Intn(width) - width/2
+
+		key := getCellKey(x, y)
+		if _, ok := cells[key]; ok {
+			continue
+		}
+
+		cells[key] = &Cell{x: x, y: y}
+	}
+
+	return cells
+}
+
+func BenchmarkGetNewCellMapNaive(b *testing.B) {
+	soup := generateSoup(100000, 2000)
+	b.ResetTimer()
+
+	// Uses the timeout escape hatch directly: PROCESS_FREQUENCY_MILLISECONDS
+	// is a live-game-loop tick budget, not a bound this throughput benchmark
+	// should be held to at 100k cells.
+	for i := 0; i < b.N; i++ {
+		getNewCellMapWithTimeout(soup, defaultRule, time.Minute)
+	}
+}
+
+func BenchmarkGetNewCellMapBitmap(b *testing.B) {
+	soup := generateSoup(100000, 2000)
+	grid := gridFromMap(soup)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		stepGrid(grid, defaultRule)
+	}
+}
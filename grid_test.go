@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+// TestGridMatchesNaive steps a glider forward under both simulators and
+// checks they agree, the same way TestHashlifeMatchesNaive validates the
+// hashlife engine against the naive one.
+func TestGridMatchesNaive(t *testing.T) {
+	glider := map[string]*Cell{
+		getCellKey(1, 0): {x: 1, y: 0},
+		getCellKey(2, 1): {x: 2, y: 1},
+		getCellKey(0, 2): {x: 0, y: 2},
+		getCellKey(1, 2): {x: 1, y: 2},
+		getCellKey(2, 2): {x: 2, y: 2},
+	}
+
+	for n := 1; n <= 32; n++ {
+		naive := NaiveSimulator{}.Step(copyCellMap(glider), n, defaultRule)
+		grid := GridSimulator{}.Step(copyCellMap(glider), n, defaultRule)
+
+		if !sameLiveCells(naive, grid) {
+			t.Fatalf("grid result diverged from naive simulator at n=%d: naive=%v grid=%v", n, naive, grid)
+		}
+	}
+}
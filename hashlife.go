@@ -0,0 +1,398 @@
+package main
+
+// Simulator advances a cell map by n generations under the given rule.
+// NaiveSimulator is the repo's original worker-pool map diff; Hashlife
+// is an alternative quadtree-based engine that memoizes repeated
+// subpatterns for large, long-running populations.
+type Simulator interface {
+	Step(cellMap map[string]*Cell, n int, rule Rule) map[string]*Cell
+}
+
+// NaiveSimulator is the original map-diffing simulator, extracted behind
+// the Simulator interface so it can be swapped for HashlifeSimulator.
+type NaiveSimulator struct{}
+
+func (NaiveSimulator) Step(cellMap map[string]*Cell, n int, rule Rule) map[string]*Cell {
+	current := cellMap
+
+	for i := 0; i < n; i++ {
+		current = getNewCellMap(current, rule)
+	}
+
+	return current
+}
+
+// node is a quadtree cell: level 0 is a single cell leaf, level k is an
+// internal node whose four children (each level k-1) cover a 2^k x 2^k
+// square between them. Nodes are immutable and hash-consed (identical
+// subtrees share a single *node), which is what lets result be memoized
+// per distinct subpattern rather than per grid position.
+type node struct {
+	level          int
+	nw, ne, sw, se *node
+	alive          bool // meaningful only when level == 0
+	population     int
+
+	// result caches the center 2^(level-1) x 2^(level-1) subsquare after
+	// 2^(level-2) generations. Only populated for level >= 2.
+	result *node
+}
+
+type nodeKey struct {
+	level          int
+	nw, ne, sw, se *node
+	alive          bool
+}
+
+// HashlifeSimulator implements Gosper's hashlife algorithm: the universe
+// is represented as a canonicalized quadtree, and each internal node
+// caches the result of advancing its center forward in time so identical
+// subpatterns are only ever simulated once.
+type HashlifeSimulator struct {
+	rule       Rule
+	canon      map[nodeKey]*node
+	emptyCache map[int]*node
+}
+
+func NewHashlifeSimulator(rule Rule) *HashlifeSimulator {
+	return &HashlifeSimulator{
+		rule:       rule,
+		canon:      make(map[nodeKey]*node),
+		emptyCache: make(map[int]*node),
+	}
+}
+
+func (s *HashlifeSimulator) leaf(alive bool) *node {
+	key := nodeKey{level: 0, alive: alive}
+	if n, ok := s.canon[key]; ok {
+		return n
+	}
+
+	population := 0
+	if alive {
+		population = 1
+	}
+
+	n := &node{level: 0, alive: alive, population: population}
+	s.canon[key] = n
+	return n
+}
+
+// join canonicalizes the node formed by the four given children, which
+// must all be the same level.
+func (s *HashlifeSimulator) join(nw, ne, sw, se *node) *node {
+	key := nodeKey{level: nw.level + 1, nw: nw, ne: ne, sw: sw, se: se}
+	if n, ok := s.canon[key]; ok {
+		return n
+	}
+
+	n := &node{
+		level: nw.level + 1,
+		nw:    nw, ne: ne, sw: sw, se: se,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	s.canon[key] = n
+	return n
+}
+
+func (s *HashlifeSimulator) emptyNode(level int) *node {
+	if n, ok := s.emptyCache[level]; ok {
+		return n
+	}
+
+	var n *node
+	if level == 0 {
+		n = s.leaf(false)
+	} else {
+		child := s.emptyNode(level - 1)
+		n = s.join(child, child, child, child)
+	}
+
+	s.emptyCache[level] = n
+	return n
+}
+
+// expand wraps nd in a new, empty-bordered node one level taller, keeping
+// nd's content centered (for level >= 1) so that future nextGeneration
+// calls never read past an edge.
+func (s *HashlifeSimulator) expand(nd *node) *node {
+	if nd.level == 0 {
+		e := s.leaf(false)
+		return s.join(e, e, e, nd)
+	}
+
+	e := s.emptyNode(nd.level - 1)
+	newNW := s.join(e, e, e, nd.nw)
+	newNE := s.join(e, e, nd.ne, e)
+	newSW := s.join(e, nd.sw, e, e)
+	newSE := s.join(nd.se, e, e, e)
+
+	return s.join(newNW, newNE, newSW, newSE)
+}
+
+// expandShift reports, in each axis, the new-local coordinate that nd's
+// own local origin (0, 0) is moved to by expand(nd) - callers use it to
+// keep an external absolute-coordinate offset in sync with the tree.
+func (s *HashlifeSimulator) expandShift(nd *node) (int, int) {
+	if nd.level == 0 {
+		return 1, 0
+	}
+
+	half := 1 << uint(nd.level-1)
+	return half, half
+}
+
+// nextGeneration returns the centered node one level smaller than nd,
+// representing nd's center after 2^(nd.level-2) generations. Requires
+// nd.level >= 2. Results are memoized on the node itself, so identical
+// subpatterns anywhere in the tree are only ever computed once.
+func (s *HashlifeSimulator) nextGeneration(nd *node) *node {
+	if nd.result != nil {
+		return nd.result
+	}
+
+	if nd.level == 2 {
+		nd.result = s.life4x4(nd)
+		return nd.result
+	}
+
+	// Build the nine overlapping (level - 1) subsquares of nd, each
+	// made of four of nd's sixteen (level - 2) grandchildren (the four
+	// corner subsquares are nd's own children).
+	n00 := nd.nw
+	n01 := s.join(nd.nw.ne, nd.ne.nw, nd.nw.se, nd.ne.sw)
+	n02 := nd.ne
+	n10 := s.join(nd.nw.sw, nd.nw.se, nd.sw.nw, nd.sw.ne)
+	n11 := s.join(nd.nw.se, nd.ne.sw, nd.sw.ne, nd.se.nw)
+	n12 := s.join(nd.ne.sw, nd.ne.se, nd.se.nw, nd.se.ne)
+	n20 := nd.sw
+	n21 := s.join(nd.sw.ne, nd.se.nw, nd.sw.se, nd.se.sw)
+	n22 := nd.se
+
+	// Each r_ij is its subsquare's center after one "half step" of
+	// 2^(nd.level-3) generations. Joining four adjacent r_ij directly
+	// would only ever advance the result by that same half step, since
+	// none of them have seen each other's cells yet. Running
+	// nextGeneration once more over each joined quadrant lets that
+	// second half step propagate, so the final result is nd's center
+	// after the full 2^(nd.level-2) generations the doc comment
+	// promises.
+	r00 := s.nextGeneration(n00)
+	r01 := s.nextGeneration(n01)
+	r02 := s.nextGeneration(n02)
+	r10 := s.nextGeneration(n10)
+	r11 := s.nextGeneration(n11)
+	r12 := s.nextGeneration(n12)
+	r20 := s.nextGeneration(n20)
+	r21 := s.nextGeneration(n21)
+	r22 := s.nextGeneration(n22)
+
+	nd.result = s.join(
+		s.nextGeneration(s.join(r00, r01, r10, r11)),
+		s.nextGeneration(s.join(r01, r02, r11, r12)),
+		s.nextGeneration(s.join(r10, r11, r20, r21)),
+		s.nextGeneration(s.join(r11, r12, r21, r22)),
+	)
+
+	return nd.result
+}
+
+// life4x4 brute-forces one generation for a level-2 (4x4 cell) node,
+// returning the resulting center 2x2 (level-1) node. This is the base
+// case nextGeneration bottoms out at.
+func (s *HashlifeSimulator) life4x4(nd *node) *node {
+	grid := [4][4]bool{
+		{nd.nw.nw.alive, nd.nw.ne.alive, nd.ne.nw.alive, nd.ne.ne.alive},
+		{nd.nw.sw.alive, nd.nw.se.alive, nd.ne.sw.alive, nd.ne.se.alive},
+		{nd.sw.nw.alive, nd.sw.ne.alive, nd.se.nw.alive, nd.se.ne.alive},
+		{nd.sw.sw.alive, nd.sw.se.alive, nd.se.sw.alive, nd.se.se.alive},
+	}
+
+	next := func(row int, col int) bool {
+		count := 0
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				if dr == 0 && dc == 0 {
+					continue
+				}
+				if grid[row+dr][col+dc] {
+					count++
+				}
+			}
+		}
+
+		if grid[row][col] {
+			return s.rule.Survive[count]
+		}
+		return s.rule.Birth[count]
+	}
+
+	return s.join(
+		s.leaf(next(1, 1)),
+		s.leaf(next(1, 2)),
+		s.leaf(next(2, 1)),
+		s.leaf(next(2, 2)),
+	)
+}
+
+// setCell returns a copy of nd with the cell at local coordinates (x, y)
+// set to alive, sharing every subtree that didn't change.
+func (s *HashlifeSimulator) setCell(nd *node, x int, y int, alive bool) *node {
+	if nd.level == 0 {
+		return s.leaf(alive)
+	}
+
+	half := 1 << uint(nd.level-1)
+
+	switch {
+	case x < half && y >= half:
+		return s.join(s.setCell(nd.nw, x, y-half, alive), nd.ne, nd.sw, nd.se)
+	case x >= half && y >= half:
+		return s.join(nd.nw, s.setCell(nd.ne, x-half, y-half, alive), nd.sw, nd.se)
+	case x < half && y < half:
+		return s.join(nd.nw, nd.ne, s.setCell(nd.sw, x, y, alive), nd.se)
+	default:
+		return s.join(nd.nw, nd.ne, nd.sw, s.setCell(nd.se, x-half, y, alive))
+	}
+}
+
+// fromMap builds a quadtree from cellMap, returning the root along with
+// the absolute (x, y) that the root's local (0, 0) corresponds to.
+func (s *HashlifeSimulator) fromMap(cellMap map[string]*Cell) (*node, int, int) {
+	if len(cellMap) == 0 {
+		return s.emptyNode(3), 0, 0
+	}
+
+	first := true
+	minX, minY, maxX, maxY := 0, 0, 0, 0
+	for _, cell := range cellMap {
+		if first {
+			minX, maxX = cell.x, cell.x
+			minY, maxY = cell.y, cell.y
+			first = false
+			continue
+		}
+		if cell.x < minX {
+			minX = cell.x
+		}
+		if cell.x > maxX {
+			maxX = cell.x
+		}
+		if cell.y < minY {
+			minY = cell.y
+		}
+		if cell.y > maxY {
+			maxY = cell.y
+		}
+	}
+
+	span := maxX - minX + 1
+	if h := maxY - minY + 1; h > span {
+		span = h
+	}
+
+	level := 1
+	for (1 << uint(level)) < span {
+		level++
+	}
+
+	root := s.emptyNode(level)
+	for _, cell := range cellMap {
+		root = s.setCell(root, cell.x-minX, cell.y-minY, true)
+	}
+
+	return root, minX, minY
+}
+
+// toMap flattens nd back into a cell map, translating local coordinates
+// by (offsetX, offsetY).
+func (s *HashlifeSimulator) toMap(nd *node, offsetX int, offsetY int) map[string]*Cell {
+	cells := make(map[string]*Cell)
+	s.collectAlive(nd, 0, 0, offsetX, offsetY, cells)
+	return cells
+}
+
+func (s *HashlifeSimulator) collectAlive(nd *node, localX int, localY int, offsetX int, offsetY int, cells map[string]*Cell) {
+	if nd.population == 0 {
+		return
+	}
+
+	if nd.level == 0 {
+		if nd.alive {
+			x := localX + offsetX
+			y := localY + offsetY
+			cells[getCellKey(x, y)] = &Cell{x: x, y: y}
+		}
+		return
+	}
+
+	half := 1 << uint(nd.level-1)
+	s.collectAlive(nd.nw, localX, localY+half, offsetX, offsetY, cells)
+	s.collectAlive(nd.ne, localX+half, localY+half, offsetX, offsetY, cells)
+	s.collectAlive(nd.sw, localX, localY, offsetX, offsetY, cells)
+	s.collectAlive(nd.se, localX+half, localY, offsetX, offsetY, cells)
+}
+
+// Step advances cellMap by n generations. Each iteration re-derives a
+// tree from the current map and pads it two levels taller than its
+// content before consuming a memoized nextGeneration call.
+// nextGeneration(nd) only ever returns nd's center half, sized exactly
+// like the content before padding - so a border of just 2^(nd.level-2),
+// one expand's worth, leaves no room of its own: content already fills
+// right up to the edge of what gets returned, and the 2^(nd.level-2)
+// generations that call advances can push live cells past that edge
+// before it's done. A second expand gives the returned region genuine
+// slack (strictly more than the generation count it advances by), so
+// growth - which can't spread faster than one cell per generation -
+// stays inside it. Re-deriving the tree from the map each iteration,
+// rather than feeding nextGeneration's result back in as the next root,
+// keeps that padding measured from the content's current extent instead
+// of compounding a shortfall left over from the last chunk. If the next
+// chunk would overshoot n, the remainder is finished with the naive
+// per-generation simulator instead of taking a partial, unmemoized
+// step.
+func (s *HashlifeSimulator) Step(cellMap map[string]*Cell, n int, rule Rule) map[string]*Cell {
+	if n <= 0 {
+		return cellMap
+	}
+
+	if rule != s.rule {
+		// nextGeneration's memoized result, and canon's hash-consing of
+		// nodes sharing it, were both computed under the old rule - a
+		// geometrically identical subpattern can step very differently
+		// under a new one. Dropping both caches forces every node (and
+		// its result) simulated from here on to be rebuilt fresh under
+		// the rule that's now active.
+		s.canon = make(map[nodeKey]*node)
+		s.emptyCache = make(map[int]*node)
+	}
+	s.rule = rule
+
+	current := cellMap
+	remaining := n
+	for remaining > 0 {
+		root, offsetX, offsetY := s.fromMap(current)
+
+		for i := 0; i < 2; i++ {
+			shiftX, shiftY := s.expandShift(root)
+			root = s.expand(root)
+			offsetX -= shiftX
+			offsetY -= shiftY
+		}
+
+		chunk := 1 << uint(root.level-2)
+		if chunk > remaining {
+			out := current
+			for i := 0; i < remaining; i++ {
+				out = getNewCellMap(out, rule)
+			}
+			return out
+		}
+
+		result := s.nextGeneration(root)
+		current = s.toMap(result, offsetX+chunk, offsetY+chunk)
+		remaining -= chunk
+	}
+
+	return current
+}
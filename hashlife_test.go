@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+// sameLiveCells reports whether two cell maps have exactly the same set
+// of live coordinates. isMapEqual compares *Cell pointer identity rather
+// than coordinates, so it isn't useful here.
+func sameLiveCells(a map[string]*Cell, b map[string]*Cell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// TestHashlifeMatchesNaive steps a glider forward under both simulators
+// and checks they agree. This is a regression test for the Step bug
+// where a loop off-by-one made the memoized fast path unreachable,
+// silently falling back to the naive simulator on every call.
+func TestHashlifeMatchesNaive(t *testing.T) {
+	glider := map[string]*Cell{
+		getCellKey(1, 0): {x: 1, y: 0},
+		getCellKey(2, 1): {x: 2, y: 1},
+		getCellKey(0, 2): {x: 0, y: 2},
+		getCellKey(1, 2): {x: 1, y: 2},
+		getCellKey(2, 2): {x: 2, y: 2},
+	}
+
+	for n := 1; n <= 32; n++ {
+		naive := NaiveSimulator{}.Step(copyCellMap(glider), n, defaultRule)
+		hashlife := NewHashlifeSimulator(defaultRule).Step(copyCellMap(glider), n, defaultRule)
+
+		if !sameLiveCells(naive, hashlife) {
+			t.Fatalf("hashlife result diverged from naive simulator at n=%d: naive=%v hashlife=%v", n, naive, hashlife)
+		}
+	}
+}
+
+// TestHashlifeMatchesNaivePulsar steps a pulsar (a period-3 oscillator
+// whose bounding box fills its tightest-fit quadtree node with no slack)
+// forward under both simulators and checks they agree. This is a
+// regression test for a Step bug where a single expand left the region
+// nextGeneration returns with no margin of its own, so growth during the
+// generations a chunk advances could push live cells past its edge and
+// have them silently dropped.
+func TestHashlifeMatchesNaivePulsar(t *testing.T) {
+	pulsar := map[string]*Cell{}
+	for _, c := range [][2]int{
+		{2, 0}, {3, 0}, {4, 0}, {8, 0}, {9, 0}, {10, 0},
+		{0, 2}, {5, 2}, {7, 2}, {12, 2},
+		{0, 3}, {5, 3}, {7, 3}, {12, 3},
+		{0, 4}, {5, 4}, {7, 4}, {12, 4},
+		{2, 5}, {3, 5}, {4, 5}, {8, 5}, {9, 5}, {10, 5},
+		{2, 7}, {3, 7}, {4, 7}, {8, 7}, {9, 7}, {10, 7},
+		{0, 8}, {5, 8}, {7, 8}, {12, 8},
+		{0, 9}, {5, 9}, {7, 9}, {12, 9},
+		{0, 10}, {5, 10}, {7, 10}, {12, 10},
+		{2, 12}, {3, 12}, {4, 12}, {8, 12}, {9, 12}, {10, 12},
+	} {
+		pulsar[getCellKey(c[0], c[1])] = &Cell{x: c[0], y: c[1]}
+	}
+
+	for n := 1; n <= 24; n++ {
+		naive := NaiveSimulator{}.Step(copyCellMap(pulsar), n, defaultRule)
+		hashlife := NewHashlifeSimulator(defaultRule).Step(copyCellMap(pulsar), n, defaultRule)
+
+		if !sameLiveCells(naive, hashlife) {
+			t.Fatalf("hashlife result diverged from naive simulator at n=%d: naive=%v hashlife=%v", n, naive, hashlife)
+		}
+	}
+}
+
+// TestHashlifeRuleSwitch steps a glider under one rule, then switches the
+// same simulator to another rule and steps further. This is a regression
+// test for a bug where nextGeneration's memoized results, and canon's
+// hash-consing of the nodes that produced them, were keyed only on tree
+// structure - not on which rule simulated them - so a result computed
+// under the old rule was returned unconditionally once the caller
+// switched the simulator to a new one.
+func TestHashlifeRuleSwitch(t *testing.T) {
+	glider := map[string]*Cell{
+		getCellKey(1, 0): {x: 1, y: 0},
+		getCellKey(2, 1): {x: 2, y: 1},
+		getCellKey(0, 2): {x: 0, y: 2},
+		getCellKey(1, 2): {x: 1, y: 2},
+		getCellKey(2, 2): {x: 2, y: 2},
+	}
+	highlife := mustParseRule("B36/S23")
+
+	sim := NewHashlifeSimulator(defaultRule)
+	_ = sim.Step(copyCellMap(glider), 4, defaultRule)
+
+	for n := 1; n <= 8; n++ {
+		naive := NaiveSimulator{}.Step(copyCellMap(glider), n, highlife)
+		hashlife := sim.Step(copyCellMap(glider), n, highlife)
+
+		if !sameLiveCells(naive, hashlife) {
+			t.Fatalf("hashlife result after switching rules diverged from naive at n=%d: naive=%v hashlife=%v", n, naive, hashlife)
+		}
+	}
+}
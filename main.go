@@ -1,19 +1,21 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"math"
+	"math/rand"
 	"os"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
 	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
 )
 
 const PROCESS_FREQUENCY_MILLISECONDS = 50
@@ -22,24 +24,62 @@ const WINDOW_WIDTH = 1000
 const WINDOW_HEIGHT = 1000
 const CELL_SIZE = 20
 
+const DEFAULT_SCALE = 1.0
+const MIN_SCALE = 0.05
+const MAX_SCALE = 20.0
+const ZOOM_STEP = 1.1
+
+// GRID_HIDE_SCALE_THRESHOLD is the Scale below which the grid overlay is
+// suppressed, since at that density it's just visual noise.
+const GRID_HIDE_SCALE_THRESHOLD = 0.3
+
+const DEFAULT_BPM = 120
+const MIN_BPM = 20
+const MAX_BPM = 600
+const BPM_STEP = 10
+
+const RANDOMIZE_EXTENT = 40
+const DEFAULT_RANDOMIZE_DENSITY = 0.35
+
 type Cell struct {
 	x int
 	y int
 }
 
 type Viewport struct {
-	offsetX int
-	offsetY int
+	offsetX float64
+	offsetY float64
+	scale   float64
+}
+
+type Tool int
+
+const (
+	ToolPencil Tool = iota
+	ToolEraser
+	ToolFill
+	ToolSelect
+)
+
+const DEFAULT_MAX_FILL_EXTENT = 5000
+
+var toolNames = map[Tool]string{
+	ToolPencil: "Pencil",
+	ToolEraser: "Eraser",
+	ToolFill:   "Fill",
+	ToolSelect: "Select",
 }
 
 func (v *Viewport) inView(x int, y int) bool {
-	positionX := x * CELL_SIZE
-	positionY := y * CELL_SIZE
-	if math.Abs(float64(positionX-v.offsetX)) > (WINDOW_WIDTH / 2) {
+	cellSize := CELL_SIZE * v.scale
+	positionX := float64(x) * cellSize
+	positionY := float64(y) * cellSize
+
+	if math.Abs(positionX-v.offsetX) > (WINDOW_WIDTH/2)+cellSize {
 		return false
 	}
 
-	if math.Abs(float64(positionY-v.offsetY)) > (WINDOW_HEIGHT / 2) {
+	if math.Abs(positionY-v.offsetY) > (WINDOW_HEIGHT/2)+cellSize {
 		return false
 	}
 
@@ -50,45 +90,68 @@ type GameState struct {
 	paused   bool
 	viewport *Viewport
 	window   *pixelgl.Window
-}
-
-func getStartingCells() (map[string]*Cell, error) {
-	cells := make(map[string]*Cell)
 
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		text := scanner.Text()
-
-		coords := strings.Split(strings.TrimSpace(text), " ")
-
-		x, err := strconv.Atoi(coords[0])
-
-		if err != nil {
-			return cells, err
-		}
-
-		y, err := strconv.Atoi(coords[1])
+	activeTool Tool
+	dragging   bool
+	clipboard  map[string]*Cell
+	selectFrom *Cell
+
+	panning            bool
+	panLastX, panLastY float64
+
+	rule       Rule
+	simulator  Simulator
+	bpm        int
+	generation int
+
+	maxFillExtent    int
+	randomizeDensity float64
+
+	// mu guards cellMap, which is the single authoritative board shared
+	// between the foreground input/render loop and the background
+	// simulation loop. Both sides mutate or replace it, so every access
+	// goes through edit, snapshot, or setCellMap rather than touching
+	// cellMap directly.
+	mu      sync.Mutex
+	cellMap map[string]*Cell
+}
 
-		if err != nil {
-			return cells, err
-		}
+// edit locks state and runs fn against the live cellMap, for mutators that
+// update it in place.
+func (s *GameState) edit(fn func(cellMap map[string]*Cell)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.cellMap)
+}
 
-		key := getCellKey(x, y)
-		if _, ok := cells[key]; ok {
-			return cells, fmt.Errorf("cell at coordinates %s already exists", key)
-		}
+// snapshot returns a standalone copy of the current cellMap, safe to read
+// or hand off without holding the lock.
+func (s *GameState) snapshot() map[string]*Cell {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyCellMap(s.cellMap)
+}
 
-		cells[key] = &Cell{x: x, y: y}
-	}
+// setCellMap replaces cellMap wholesale, for results computed from a
+// snapshot (e.g. a completed simulation step).
+func (s *GameState) setCellMap(cellMap map[string]*Cell) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cellMap = cellMap
+}
 
-	if err := scanner.Err(); err != nil {
+// getStartingCells reads the initial pattern from stdin, along with the
+// rule it declared, if any (currently only an RLE header's "rule" field).
+func getStartingCells() (map[string]*Cell, *Rule, error) {
+	cells, rule, err := loadPattern(os.Stdin)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, "reading standard input:", err)
 	}
 
-	return cells, nil
+	return cells, rule, err
 }
 
-func run(cellMap map[string]*Cell) {
+func run(cellMap map[string]*Cell, rule Rule, simulator Simulator, maxFillExtent int, randomizeDensity float64) {
 	cfg := pixelgl.WindowConfig{
 		Title:  "Game Of Life",
 		Bounds: pixel.R(0, 0, WINDOW_WIDTH, WINDOW_HEIGHT),
@@ -101,42 +164,82 @@ func run(cellMap map[string]*Cell) {
 	}
 
 	state := &GameState{
-		paused:   true,
-		viewport: &Viewport{offsetX: 0, offsetY: 0},
-		window:   win,
+		paused:           true,
+		viewport:         &Viewport{offsetX: 0, offsetY: 0, scale: DEFAULT_SCALE},
+		window:           win,
+		activeTool:       ToolPencil,
+		rule:             rule,
+		simulator:        simulator,
+		bpm:              DEFAULT_BPM,
+		cellMap:          cellMap,
+		maxFillExtent:    maxFillExtent,
+		randomizeDensity: randomizeDensity,
 	}
 
 	// Draw initial pattern
 	if !win.Closed() {
-		draw(cellMap, state)
+		draw(state)
 		win.Update()
 	}
 
-	startLoop(cellMap, state)
+	startLoop(state)
 }
 
 func main() {
-	cellMap, err := getStartingCells()
+	rulestring := flag.String("rule", defaultRule.Name, "Golly-style B/S rulestring, e.g. B3/S23")
+	simulatorName := flag.String("simulator", "naive", "simulation engine to use: naive, hashlife, or bitmap")
+	maxFillExtent := flag.Int("max-fill-extent", DEFAULT_MAX_FILL_EXTENT, "maximum number of cells the fill tool will visit")
+	randomizeDensity := flag.Float64("randomize-density", DEFAULT_RANDOMIZE_DENSITY, "fraction of cells set alive by the randomize tool")
+	flag.Parse()
+
+	ruleFromFlag := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "rule" {
+			ruleFromFlag = true
+		}
+	})
 
+	rule, err := parseRule(*rulestring)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	cellMap, patternRule, err := getStartingCells()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// An explicit -rule flag wins over whatever the pattern file
+	// declared; absent that, a rule read from the file (currently only
+	// an RLE header's "rule" field) wins over the default.
+	if !ruleFromFlag && patternRule != nil {
+		rule = *patternRule
+	}
+
+	var simulator Simulator
+	switch *simulatorName {
+	case "hashlife":
+		simulator = NewHashlifeSimulator(rule)
+	case "bitmap":
+		simulator = GridSimulator{}
+	default:
+		simulator = NaiveSimulator{}
+	}
+
 	pixelgl.Run(func() {
-		run(cellMap)
+		run(cellMap, rule, simulator, *maxFillExtent, *randomizeDensity)
 	})
 }
 
-func startLoop(cellMap map[string]*Cell, state *GameState) {
-	newMaps := make(chan map[string]*Cell, 1)
-	currentMap := cellMap
+func startLoop(state *GameState) {
+	ticks := make(chan struct{}, 1)
 
-	go startProcessLoop(cellMap, newMaps, state)
+	go startProcessLoop(state, ticks)
 
 	for !state.window.Closed() {
 		select {
-		case newMap := <-newMaps:
-			draw(newMap, state)
+		case <-ticks:
+			draw(state)
 		default:
 			// pass
 		}
@@ -146,102 +249,522 @@ func startLoop(cellMap map[string]*Cell, state *GameState) {
 		}
 
 		if state.window.JustPressed(pixelgl.KeyUp) {
-			state.viewport.offsetY += CELL_SIZE
-			draw(currentMap, state)
+			state.viewport.offsetY += CELL_SIZE * state.viewport.scale
+			draw(state)
 		}
 
 		if state.window.JustPressed(pixelgl.KeyDown) {
-			state.viewport.offsetY -= CELL_SIZE
-			draw(currentMap, state)
+			state.viewport.offsetY -= CELL_SIZE * state.viewport.scale
+			draw(state)
 		}
 
 		if state.window.JustPressed(pixelgl.KeyLeft) {
-			state.viewport.offsetX -= CELL_SIZE
-			draw(currentMap, state)
+			state.viewport.offsetX -= CELL_SIZE * state.viewport.scale
+			draw(state)
 		}
 
 		if state.window.JustPressed(pixelgl.KeyRight) {
-			state.viewport.offsetX += CELL_SIZE
-			draw(currentMap, state)
+			state.viewport.offsetX += CELL_SIZE * state.viewport.scale
+			draw(state)
+		}
+
+		if handleZoomAndPan(state) {
+			draw(state)
+		}
+
+		if state.window.JustPressed(pixelgl.Key1) {
+			state.activeTool = ToolPencil
+		}
+
+		if state.window.JustPressed(pixelgl.Key2) {
+			state.activeTool = ToolEraser
+		}
+
+		if state.window.JustPressed(pixelgl.Key3) {
+			state.activeTool = ToolFill
+		}
+
+		if state.window.JustPressed(pixelgl.Key4) {
+			state.activeTool = ToolSelect
+		}
+
+		if handleMouse(state) {
+			draw(state)
+		}
+
+		if state.window.JustPressed(pixelgl.KeyS) {
+			if err := saveCurrentMap(state.snapshot()); err != nil {
+				fmt.Fprintln(os.Stderr, "saving pattern:", err)
+			}
+		}
+
+		if state.window.JustPressed(pixelgl.KeyP) {
+			state.rule = nextRulePreset(state.rule)
+		}
+
+		if state.window.JustPressed(pixelgl.KeyEqual) {
+			state.bpm += BPM_STEP
+			if state.bpm > MAX_BPM {
+				state.bpm = MAX_BPM
+			}
+		}
+
+		if state.window.JustPressed(pixelgl.KeyMinus) {
+			state.bpm -= BPM_STEP
+			if state.bpm < MIN_BPM {
+				state.bpm = MIN_BPM
+			}
+		}
+
+		if state.window.JustPressed(pixelgl.KeyR) {
+			centerX, centerY := viewportCenterCell(state.viewport)
+			state.edit(func(cellMap map[string]*Cell) {
+				randomizeRegion(cellMap, centerX, centerY, RANDOMIZE_EXTENT, state.randomizeDensity)
+			})
+			draw(state)
+		}
+
+		if state.window.JustPressed(pixelgl.KeyC) {
+			state.edit(clearCellMap)
+			draw(state)
+		}
+
+		if state.paused && state.window.JustPressed(pixelgl.KeyN) {
+			newMap := state.simulator.Step(state.snapshot(), 1, state.rule)
+			state.generation++
+			state.setCellMap(newMap)
+			draw(state)
 		}
 
 		state.window.Update()
 	}
 }
 
-func startProcessLoop(startingMap map[string]*Cell, maps chan map[string]*Cell, state *GameState) {
-	nextMapToProcess := startingMap
+// handleMouse applies the active tool based on the current mouse state and
+// reports whether the board changed and needs a redraw.
+func handleMouse(state *GameState) bool {
+	win := state.window
+
+	shiftHeld := win.Pressed(pixelgl.KeyLeftShift) || win.Pressed(pixelgl.KeyRightShift)
+
+	if win.JustPressed(pixelgl.MouseButtonLeft) {
+		x, y := worldToGridCoords(win, state.viewport)
+
+		switch {
+		case shiftHeld:
+			state.edit(func(cellMap map[string]*Cell) { floodFill(cellMap, x, y, state.maxFillExtent) })
+			return true
+		case state.activeTool == ToolSelect:
+			state.selectFrom = &Cell{x: x, y: y}
+			return false
+		case state.activeTool == ToolFill:
+			state.edit(func(cellMap map[string]*Cell) { floodFill(cellMap, x, y, state.maxFillExtent) })
+			return true
+		case state.activeTool == ToolEraser:
+			state.edit(func(cellMap map[string]*Cell) { eraseCell(cellMap, x, y) })
+			state.dragging = true
+			return true
+		default:
+			state.edit(func(cellMap map[string]*Cell) { paintCell(cellMap, x, y) })
+			state.dragging = true
+			return true
+		}
+	}
+
+	if win.JustReleased(pixelgl.MouseButtonLeft) {
+		state.dragging = false
+
+		if state.activeTool == ToolSelect && state.selectFrom != nil {
+			x, y := worldToGridCoords(win, state.viewport)
+			state.clipboard = copyRegion(state.snapshot(), state.selectFrom.x, state.selectFrom.y, x, y)
+			state.selectFrom = nil
+		}
+
+		return false
+	}
+
+	if win.Pressed(pixelgl.MouseButtonLeft) && state.dragging {
+		x, y := worldToGridCoords(win, state.viewport)
+
+		if state.activeTool == ToolEraser {
+			state.edit(func(cellMap map[string]*Cell) { eraseCell(cellMap, x, y) })
+		} else if state.activeTool == ToolPencil {
+			state.edit(func(cellMap map[string]*Cell) { paintCell(cellMap, x, y) })
+		}
+
+		return true
+	}
+
+	if win.JustPressed(pixelgl.MouseButtonRight) {
+		x, y := worldToGridCoords(win, state.viewport)
+		state.edit(func(cellMap map[string]*Cell) { eraseCell(cellMap, x, y) })
+		return true
+	}
+
+	if win.JustPressed(pixelgl.KeyV) && state.clipboard != nil {
+		x, y := worldToGridCoords(win, state.viewport)
+		state.edit(func(cellMap map[string]*Cell) { pasteRegion(cellMap, state.clipboard, x, y) })
+		return true
+	}
+
+	return false
+}
+
+// handleZoomAndPan applies mouse-wheel zoom (centered on the cursor) and
+// middle-mouse-button drag panning, reporting whether the viewport
+// changed and needs a redraw.
+func handleZoomAndPan(state *GameState) bool {
+	win := state.window
+	viewport := state.viewport
+	changed := false
+
+	if scroll := win.MouseScroll(); scroll.Y != 0 {
+		cursor := win.MousePosition()
+
+		oldScale := viewport.scale
+		newScale := oldScale * math.Pow(ZOOM_STEP, scroll.Y)
+		if newScale < MIN_SCALE {
+			newScale = MIN_SCALE
+		}
+		if newScale > MAX_SCALE {
+			newScale = MAX_SCALE
+		}
+
+		cellSizeOld := CELL_SIZE * oldScale
+		cellSizeNew := CELL_SIZE * newScale
+
+		widthOffset := (WINDOW_WIDTH / 2) - viewport.offsetX
+		heightOffset := (WINDOW_HEIGHT / 2) - viewport.offsetY
+
+		worldX := (cursor.X - widthOffset) / cellSizeOld
+		worldY := (cursor.Y - heightOffset) / cellSizeOld
+
+		viewport.offsetX += worldX * (cellSizeNew - cellSizeOld)
+		viewport.offsetY += worldY * (cellSizeNew - cellSizeOld)
+		viewport.scale = newScale
+
+		changed = true
+	}
+
+	if win.JustPressed(pixelgl.MouseButtonMiddle) {
+		pos := win.MousePosition()
+		state.panning = true
+		state.panLastX, state.panLastY = pos.X, pos.Y
+	}
 
+	if win.JustReleased(pixelgl.MouseButtonMiddle) {
+		state.panning = false
+	}
+
+	if state.panning && win.Pressed(pixelgl.MouseButtonMiddle) {
+		pos := win.MousePosition()
+
+		viewport.offsetX -= pos.X - state.panLastX
+		viewport.offsetY -= pos.Y - state.panLastY
+		state.panLastX, state.panLastY = pos.X, pos.Y
+
+		changed = true
+	}
+
+	return changed
+}
+
+// worldToGridCoords translates the window-pixel mouse position into grid
+// coordinates, accounting for the current viewport offset.
+func worldToGridCoords(win *pixelgl.Window, viewport *Viewport) (int, int) {
+	pos := win.MousePosition()
+	cellSize := CELL_SIZE * viewport.scale
+
+	widthOffset := (WINDOW_WIDTH / 2) - viewport.offsetX
+	heightOffset := (WINDOW_HEIGHT / 2) - viewport.offsetY
+
+	x := int(math.Round((pos.X - widthOffset) / cellSize))
+	y := int(math.Round((pos.Y - heightOffset) / cellSize))
+
+	return x, y
+}
+
+func paintCell(cellMap map[string]*Cell, x int, y int) {
+	key := getCellKey(x, y)
+	cellMap[key] = &Cell{x: x, y: y}
+}
+
+func eraseCell(cellMap map[string]*Cell, x int, y int) {
+	key := getCellKey(x, y)
+	delete(cellMap, key)
+}
+
+// floodFill flips the alive/dead state of the connected region starting at
+// (x, y), stopping once maxExtent cells have been visited.
+func floodFill(cellMap map[string]*Cell, x int, y int, maxExtent int) {
+	startKey := getCellKey(x, y)
+	_, startAlive := cellMap[startKey]
+
+	visited := make(map[string]bool)
+	queue := []Cell{{x: x, y: y}}
+
+	for len(queue) > 0 && len(visited) < maxExtent {
+		cell := queue[0]
+		queue = queue[1:]
+
+		key := getCellKey(cell.x, cell.y)
+		if visited[key] {
+			continue
+		}
+		visited[key] = true
+
+		_, alive := cellMap[key]
+		if alive != startAlive {
+			continue
+		}
+
+		if alive {
+			delete(cellMap, key)
+		} else {
+			cellMap[key] = &Cell{x: cell.x, y: cell.y}
+		}
+
+		queue = append(queue,
+			Cell{x: cell.x - 1, y: cell.y},
+			Cell{x: cell.x + 1, y: cell.y},
+			Cell{x: cell.x, y: cell.y - 1},
+			Cell{x: cell.x, y: cell.y + 1},
+		)
+	}
+}
+
+// copyRegion extracts the rectangle bounded by the two corner coordinates
+// (inclusive, order-independent) into a standalone cell map.
+func copyRegion(cellMap map[string]*Cell, x1 int, y1 int, x2 int, y2 int) map[string]*Cell {
+	minX, maxX := x1, x2
+	if minX > maxX {
+		minX, maxX = maxX, minX
+	}
+
+	minY, maxY := y1, y2
+	if minY > maxY {
+		minY, maxY = maxY, minY
+	}
+
+	region := make(map[string]*Cell)
+	for _, cell := range cellMap {
+		if cell.x < minX || cell.x > maxX || cell.y < minY || cell.y > maxY {
+			continue
+		}
+
+		region[getCellKey(cell.x-minX, cell.y-minY)] = &Cell{x: cell.x - minX, y: cell.y - minY}
+	}
+
+	return region
+}
+
+// pasteRegion stamps a previously copied region into cellMap with its
+// origin translated to (x, y).
+func pasteRegion(cellMap map[string]*Cell, clipboard map[string]*Cell, x int, y int) {
+	for _, cell := range clipboard {
+		destX := cell.x + x
+		destY := cell.y + y
+		cellMap[getCellKey(destX, destY)] = &Cell{x: destX, y: destY}
+	}
+}
+
+// viewportCenterCell returns the grid coordinate currently rendered at
+// the center of the window.
+func viewportCenterCell(viewport *Viewport) (int, int) {
+	cellSize := CELL_SIZE * viewport.scale
+	return int(math.Round(viewport.offsetX / cellSize)), int(math.Round(viewport.offsetY / cellSize))
+}
+
+// randomizeRegion scatters live cells with the given density across an
+// extent x extent square centered on (centerX, centerY).
+func randomizeRegion(cellMap map[string]*Cell, centerX int, centerY int, extent int, density float64) {
+	for x := centerX - extent/2; x < centerX+extent/2; x++ {
+		for y := centerY - extent/2; y < centerY+extent/2; y++ {
+			key := getCellKey(x, y)
+			if rand.Float64() < density {
+				cellMap[key] = &Cell{x: x, y: y}
+			} else {
+				delete(cellMap, key)
+			}
+		}
+	}
+}
+
+func clearCellMap(cellMap map[string]*Cell) {
+	for k := range cellMap {
+		delete(cellMap, k)
+	}
+}
+
+// saveCurrentMap writes cellMap to a timestamped RLE file in the current
+// directory.
+func saveCurrentMap(cellMap map[string]*Cell) error {
+	filename := fmt.Sprintf("gameoflife-%s.rle", time.Now().Format("20060102-150405"))
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return writeRLE(file, cellMap)
+}
+
+// nextRulePreset returns the preset following the given rule in
+// rulePresets, wrapping around to the first when none match (or the
+// current rule is a custom, non-preset rule).
+func nextRulePreset(current Rule) Rule {
+	for i, preset := range rulePresets {
+		if preset.Name == current.Name {
+			return rulePresets[(i+1)%len(rulePresets)]
+		}
+	}
+
+	return rulePresets[0]
+}
+
+// startProcessLoop advances state.cellMap on its own clock, independent of
+// the foreground input/render loop. Each tick it takes a snapshot (so the
+// simulator, which can take up to tens of milliseconds, never blocks the
+// UI thread behind the lock), steps that snapshot, and writes the result
+// back as the new authoritative board.
+func startProcessLoop(state *GameState, ticks chan<- struct{}) {
 	for {
-		time.Sleep(PROCESS_FREQUENCY_MILLISECONDS * time.Millisecond)
+		tickMillis := 60000 / state.bpm
+		time.Sleep(time.Duration(tickMillis) * time.Millisecond)
 
 		if state.paused {
 			continue
 		}
 
-		newMap := getNewCellMap(nextMapToProcess)
+		newMap := state.simulator.Step(state.snapshot(), 1, state.rule)
+		state.generation++
+		state.setCellMap(newMap)
 
-		// Copy to prevent map write during iteration
-		nextMapToProcess = copyCellMap(newMap)
-		maps <- copyCellMap(newMap)
+		select {
+		case ticks <- struct{}{}:
+		default:
+			// A redraw is already pending; the next draw() will pick up
+			// this tick's result since it re-snapshots cellMap fresh.
+		}
 	}
 }
 
-func draw(cellMap map[string]*Cell, state *GameState) {
-	cellSpacer := CELL_SIZE / 2
-	widthOffset := (WINDOW_WIDTH / 2) - (state.viewport.offsetX)
-	heightOffset := (WINDOW_HEIGHT / 2) - (state.viewport.offsetY)
+func draw(state *GameState) {
+	cellMap := state.snapshot()
+	viewport := state.viewport
+	cellSize := CELL_SIZE * viewport.scale
+	cellSpacer := cellSize / 2
+	widthOffset := (WINDOW_WIDTH / 2) - viewport.offsetX
+	heightOffset := (WINDOW_HEIGHT / 2) - viewport.offsetY
 
 	state.window.Clear(colornames.Black)
 
+	// Batch every cell into a single imdraw.IMDraw instead of allocating
+	// one per cell, so zoomed-out views with tens of thousands of cells
+	// stay smooth.
+	imd := imdraw.New(nil)
 	for _, cell := range cellMap {
-		if !state.viewport.inView(cell.x, cell.y) {
+		if !viewport.inView(cell.x, cell.y) {
 			continue
 		}
 
-		cellCenterX := (cell.x * CELL_SIZE) + widthOffset
-		cellCenterY := (cell.y * CELL_SIZE) + heightOffset
+		cellCenterX := float64(cell.x)*cellSize + widthOffset
+		cellCenterY := float64(cell.y)*cellSize + heightOffset
 
-		imd := imdraw.New(nil)
-
-		imd.Push(pixel.V(float64(cellCenterX-cellSpacer), float64(cellCenterY-cellSpacer)))
-		imd.Push(pixel.V(float64(cellCenterX+cellSpacer), float64(cellCenterY-cellSpacer)))
-		imd.Push(pixel.V(float64(cellCenterX+cellSpacer), float64(cellCenterY+cellSpacer)))
-		imd.Push(pixel.V(float64(cellCenterX-cellSpacer), float64(cellCenterY+cellSpacer)))
+		imd.Push(pixel.V(cellCenterX-cellSpacer, cellCenterY-cellSpacer))
+		imd.Push(pixel.V(cellCenterX+cellSpacer, cellCenterY-cellSpacer))
+		imd.Push(pixel.V(cellCenterX+cellSpacer, cellCenterY+cellSpacer))
+		imd.Push(pixel.V(cellCenterX-cellSpacer, cellCenterY+cellSpacer))
 		imd.Polygon(0)
+	}
+	imd.Draw(state.window)
 
-		imd.Draw(state.window)
+	if viewport.scale >= GRID_HIDE_SCALE_THRESHOLD {
+		drawGrid(state)
 	}
+	drawToolPalette(state)
+	drawHUD(state, len(cellMap))
+}
+
+var hudAtlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
 
-	drawGrid(state)
+// drawHUD renders the active rule, BPM, generation count, and population
+// in the top-right corner, like a live instrument readout.
+func drawHUD(state *GameState, population int) {
+	txt := text.New(pixel.V(WINDOW_WIDTH-160, WINDOW_HEIGHT-20), hudAtlas)
+	txt.Color = colornames.White
+	fmt.Fprintf(txt, "Rule: %s\nBPM: %d\nGen: %d\nPop: %d", state.rule.Name, state.bpm, state.generation, population)
+
+	txt.Draw(state.window, pixel.IM)
 }
 
-func drawGrid(state *GameState) {
-	for i := CELL_SIZE / 2; i < WINDOW_WIDTH; i += CELL_SIZE {
+// drawToolPalette renders a small indicator of the active editing tool in
+// the top-left corner of the window.
+func drawToolPalette(state *GameState) {
+	tools := []Tool{ToolPencil, ToolEraser, ToolFill, ToolSelect}
+	swatchSize := 16.0
+	padding := 4.0
+
+	for i, tool := range tools {
+		x := padding + float64(i)*(swatchSize+padding)
+		y := WINDOW_HEIGHT - padding - swatchSize
+
 		imd := imdraw.New(nil)
-		imd.Color = colornames.Gray
+		if tool == state.activeTool {
+			imd.Color = colornames.Yellow
+		} else {
+			imd.Color = colornames.Gray
+		}
 
-		imd.Push(pixel.V(float64(i), 0))
-		imd.Push(pixel.V(float64(i), WINDOW_HEIGHT))
+		imd.Push(pixel.V(x, y))
+		imd.Push(pixel.V(x+swatchSize, y))
+		imd.Push(pixel.V(x+swatchSize, y+swatchSize))
+		imd.Push(pixel.V(x, y+swatchSize))
+		imd.Polygon(0)
 
-		imd.Line(1)
 		imd.Draw(state.window)
+
+		txt := text.New(pixel.V(x, y-12), hudAtlas)
+		txt.Color = colornames.White
+		fmt.Fprint(txt, toolNames[tool])
+		txt.Draw(state.window, pixel.IM.Scaled(txt.Orig, 0.7))
 	}
+}
 
-	for i := CELL_SIZE / 2; i < WINDOW_HEIGHT; i += CELL_SIZE {
-		imd := imdraw.New(nil)
-		imd.Color = colornames.Gray
+func drawGrid(state *GameState) {
+	cellSize := CELL_SIZE * state.viewport.scale
 
-		imd.Push(pixel.V(0, float64(i)))
-		imd.Push(pixel.V(WINDOW_WIDTH, float64(i)))
+	imd := imdraw.New(nil)
+	imd.Color = colornames.Gray
 
+	for i := cellSize / 2; i < WINDOW_WIDTH; i += cellSize {
+		imd.Push(pixel.V(i, 0))
+		imd.Push(pixel.V(i, WINDOW_HEIGHT))
 		imd.Line(1)
-		imd.Draw(state.window)
 	}
 
+	for i := cellSize / 2; i < WINDOW_HEIGHT; i += cellSize {
+		imd.Push(pixel.V(0, i))
+		imd.Push(pixel.V(WINDOW_WIDTH, i))
+		imd.Line(1)
+	}
+
+	imd.Draw(state.window)
 }
 
-func getNewCellMap(currentMap map[string]*Cell) map[string]*Cell {
+// getNewCellMap computes the next generation, bailing out if the worker
+// pool doesn't finish within PROCESS_FREQUENCY_MILLISECONDS - the budget
+// the live game loop needs a tick to land inside.
+func getNewCellMap(currentMap map[string]*Cell, rule Rule) map[string]*Cell {
+	return getNewCellMapWithTimeout(currentMap, rule, PROCESS_FREQUENCY_MILLISECONDS*time.Millisecond)
+}
+
+// getNewCellMapWithTimeout is getNewCellMap with the worker-pool watchdog
+// exposed, so throughput benchmarks on inputs well beyond what a live tick
+// needs to process can give the workers as long as they need instead of
+// tripping the interactive budget.
+func getNewCellMapWithTimeout(currentMap map[string]*Cell, rule Rule, timeout time.Duration) map[string]*Cell {
 	cellCount := len(currentMap)
 	newMap := make(map[string]*Cell)
 
@@ -253,14 +776,14 @@ func getNewCellMap(currentMap map[string]*Cell) map[string]*Cell {
 			i++
 		}
 
-		processCells(currentMap, newMap, chunks)
+		processCells(currentMap, newMap, chunks, rule)
 	} else {
 		chunks := chunkCells(currentMap, WORKER_COUNT)
 		totalChunks := len(chunks)
 
 		processedChunkMaps := make(chan map[string]*Cell, totalChunks)
 		for _, chunk := range chunks {
-			go processChunk(currentMap, chunk, processedChunkMaps)
+			go processChunk(currentMap, chunk, processedChunkMaps, rule)
 		}
 
 		completeChunks := 0
@@ -271,7 +794,7 @@ func getNewCellMap(currentMap map[string]*Cell) map[string]*Cell {
 					newMap[k] = v
 				}
 				completeChunks++
-			case <-time.After(PROCESS_FREQUENCY_MILLISECONDS * time.Millisecond):
+			case <-time.After(timeout):
 				panic("Took to long to process cells")
 			}
 		}
@@ -280,31 +803,31 @@ func getNewCellMap(currentMap map[string]*Cell) map[string]*Cell {
 	return newMap
 }
 
-func processChunk(currentMap map[string]*Cell, currentChunk []*Cell, processedChunk chan map[string]*Cell) {
+func processChunk(currentMap map[string]*Cell, currentChunk []*Cell, processedChunk chan map[string]*Cell, rule Rule) {
 	chunkMap := make(map[string]*Cell)
 
-	processCells(currentMap, chunkMap, currentChunk)
+	processCells(currentMap, chunkMap, currentChunk, rule)
 
 	processedChunk <- chunkMap
 }
 
-func processCells(currentMap map[string]*Cell, newMap map[string]*Cell, cells []*Cell) {
+func processCells(currentMap map[string]*Cell, newMap map[string]*Cell, cells []*Cell, rule Rule) {
 	for _, cell := range cells {
 		// Due to round robin during chunking, some slice indexes might be 0
 		if cell == nil {
 			continue
 		}
 
-		if !shouldKillCell(currentMap, cell.x, cell.y) {
+		if !shouldKillCell(currentMap, cell.x, cell.y, rule) {
 			key := getCellKey(cell.x, cell.y)
 			newMap[key] = cell
 		}
 
-		reviveCells(currentMap, newMap, cell)
+		reviveCells(currentMap, newMap, cell, rule)
 	}
 }
 
-func reviveCells(currentMap map[string]*Cell, newMap map[string]*Cell, cell *Cell) {
+func reviveCells(currentMap map[string]*Cell, newMap map[string]*Cell, cell *Cell, rule Rule) {
 	for i := -1; i <= 1; i++ {
 		for j := -1; j <= 1; j++ {
 			// Skip center
@@ -327,31 +850,23 @@ func reviveCells(currentMap map[string]*Cell, newMap map[string]*Cell, cell *Cel
 				continue
 			}
 
-			if shouldReviveCell(currentMap, currentX, currentY) {
+			if shouldReviveCell(currentMap, currentX, currentY, rule) {
 				newMap[key] = &Cell{x: currentX, y: currentY}
 			}
 		}
 	}
 }
 
-func shouldKillCell(cellMap map[string]*Cell, x int, y int) bool {
+func shouldKillCell(cellMap map[string]*Cell, x int, y int, rule Rule) bool {
 	neighborCount := getCellNeighborCount(cellMap, x, y)
 
-	if neighborCount < 2 {
-		return true
-	}
-
-	if neighborCount > 3 {
-		return true
-	}
-
-	return false
+	return !rule.Survive[neighborCount]
 }
 
-func shouldReviveCell(cellMap map[string]*Cell, x int, y int) bool {
+func shouldReviveCell(cellMap map[string]*Cell, x int, y int, rule Rule) bool {
 	neighborCount := getCellNeighborCount(cellMap, x, y)
 
-	return neighborCount == 3
+	return rule.Birth[neighborCount]
 }
 
 func getCellNeighborCount(cellMap map[string]*Cell, x int, y int) int {
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// PatternLoader parses a specific Game of Life file format into a cell
+// map. Both methods see every line of the input up front, since several
+// formats need to look past comment/header lines before they can tell
+// whether they apply.
+type PatternLoader interface {
+	// sniff reports whether lines looks like this loader's format.
+	sniff(lines []string) bool
+	// load parses lines (which includes any header/comment lines) into
+	// a cell map, along with the rule the file declared, if any. The
+	// returned rule is nil for formats (or files) that don't carry one.
+	load(lines []string) (map[string]*Cell, *Rule, error)
+}
+
+var patternLoaders = []PatternLoader{
+	life106Loader{},
+	life105Loader{},
+	rleLoader{},
+	plaintextLoader{},
+	coordLoader{},
+}
+
+// loadPattern autodetects the pattern format and dispatches to the
+// matching loader, falling back to the repo's original "x y" coordinate
+// format. The returned rule is nil unless the file itself declared one
+// (currently only the RLE format's header does).
+func loadPattern(r io.Reader) (map[string]*Cell, *Rule, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(lines) == 0 {
+		return make(map[string]*Cell), nil, nil
+	}
+
+	for _, loader := range patternLoaders {
+		if loader.sniff(lines) {
+			return loader.load(lines)
+		}
+	}
+
+	return coordLoader{}.load(lines)
+}
+
+// coordLoader is the repo's original "x y" per-line stdin format.
+type coordLoader struct{}
+
+func (coordLoader) sniff(lines []string) bool {
+	return true
+}
+
+func (coordLoader) load(lines []string) (map[string]*Cell, *Rule, error) {
+	cells := make(map[string]*Cell)
+
+	for _, text := range lines {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		coords := strings.Split(text, " ")
+
+		x, err := strconv.Atoi(coords[0])
+		if err != nil {
+			return cells, nil, err
+		}
+
+		y, err := strconv.Atoi(coords[1])
+		if err != nil {
+			return cells, nil, err
+		}
+
+		key := getCellKey(x, y)
+		if _, ok := cells[key]; ok {
+			return cells, nil, fmt.Errorf("cell at coordinates %s already exists", key)
+		}
+
+		cells[key] = &Cell{x: x, y: y}
+	}
+
+	return cells, nil, nil
+}
+
+// life106Loader parses the Life 1.06 format: a header line followed by one
+// "x y" coordinate pair per line.
+type life106Loader struct{}
+
+func (life106Loader) sniff(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "#Life 1.06")
+}
+
+func (life106Loader) load(lines []string) (map[string]*Cell, *Rule, error) {
+	return coordLoader{}.load(lines[1:])
+}
+
+// life105Loader parses the Life 1.05 format: a header followed by
+// "#P x y" block markers and "." / "*" rows relative to the block origin.
+type life105Loader struct{}
+
+func (life105Loader) sniff(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "#Life 1.05")
+}
+
+func (life105Loader) load(lines []string) (map[string]*Cell, *Rule, error) {
+	cells := make(map[string]*Cell)
+
+	blockX, blockY := 0, 0
+	row := 0
+
+	for _, line := range lines[1:] {
+		if line == "" || strings.HasPrefix(line, "#D") || strings.HasPrefix(line, "#N") || strings.HasPrefix(line, "#R") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#P") {
+			fields := strings.Fields(line)
+			if len(fields) != 3 {
+				return cells, nil, fmt.Errorf("malformed #P block header: %q", line)
+			}
+
+			x, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return cells, nil, err
+			}
+
+			y, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return cells, nil, err
+			}
+
+			blockX, blockY = x, y
+			row = 0
+			continue
+		}
+
+		for col, r := range line {
+			if r == '*' {
+				x, y := blockX+col, blockY+row
+				cells[getCellKey(x, y)] = &Cell{x: x, y: y}
+			}
+		}
+		row++
+	}
+
+	return cells, nil, nil
+}
+
+// rleLoader parses Golly's run-length-encoded format:
+//
+//	#N Glider
+//	#C comments are ignored
+//	x = 3, y = 3, rule = B3/S23
+//	bo$2bo$3o!
+//
+// The header is the first non-comment line, which may be preceded by any
+// number of "#"-prefixed comment lines (as in real Golly/conwaylife.com
+// exports), not necessarily the very first line of the file.
+type rleLoader struct{}
+
+func (rleLoader) sniff(lines []string) bool {
+	header, _, ok := firstRLEHeaderLine(lines)
+	if !ok {
+		return false
+	}
+
+	return strings.HasPrefix(strings.ReplaceAll(header, " ", ""), "x=")
+}
+
+// firstRLEHeaderLine returns the first non-blank, non-comment line along
+// with the index immediately after it, or ok == false if every line is
+// blank or a comment.
+func firstRLEHeaderLine(lines []string) (string, int, bool) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return trimmed, i + 1, true
+	}
+
+	return "", 0, false
+}
+
+func (rleLoader) load(lines []string) (map[string]*Cell, *Rule, error) {
+	cells := make(map[string]*Cell)
+
+	header, bodyStart, ok := firstRLEHeaderLine(lines)
+	if !ok {
+		return cells, nil, fmt.Errorf("rle: missing header line")
+	}
+
+	width, height, rule, err := parseRLEHeader(header)
+	if err != nil {
+		return cells, nil, err
+	}
+
+	var body strings.Builder
+	for _, line := range lines[bodyStart:] {
+		body.WriteString(line)
+	}
+
+	x, y := 0, 0
+	runCount := 0
+
+	for _, ch := range body.String() {
+		if ch >= '0' && ch <= '9' {
+			runCount = runCount*10 + int(ch-'0')
+			continue
+		}
+
+		count := runCount
+		if count == 0 {
+			count = 1
+		}
+		runCount = 0
+
+		switch ch {
+		case 'b':
+			x += count
+		case 'o':
+			for i := 0; i < count; i++ {
+				cells[getCellKey(x, y)] = &Cell{x: x, y: y}
+				x++
+			}
+		case '$':
+			y += count
+			x = 0
+		case '!':
+			return centerCells(cells, width, height), rule, nil
+		default:
+			return cells, nil, fmt.Errorf("rle: unexpected token %q", ch)
+		}
+	}
+
+	return centerCells(cells, width, height), rule, nil
+}
+
+// parseRLEHeader parses a "x = .., y = .., rule = .." RLE header line.
+// The returned rule is nil when the header has no rule field, and falls
+// back to defaultRule (with no error) when the field is present but
+// unparseable, matching parseRule's own fallback behavior.
+func parseRLEHeader(header string) (int, int, *Rule, error) {
+	width, height := 0, 0
+	var rule *Rule
+
+	for _, field := range strings.Split(header, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "x":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			width = n
+		case "y":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			height = n
+		case "rule":
+			parsed, _ := parseRule(value)
+			rule = &parsed
+		}
+	}
+
+	return width, height, rule, nil
+}
+
+// centerCells shifts a pattern so that it is centered on the origin,
+// assuming it was drawn into a width x height bounding box starting at
+// (0, 0).
+func centerCells(cells map[string]*Cell, width int, height int) map[string]*Cell {
+	offsetX := width / 2
+	offsetY := height / 2
+
+	centered := make(map[string]*Cell)
+	for _, cell := range cells {
+		x := cell.x - offsetX
+		y := cell.y - offsetY
+		centered[getCellKey(x, y)] = &Cell{x: x, y: y}
+	}
+
+	return centered
+}
+
+// plaintextLoader parses the plaintext .cells format: "!" comment lines
+// followed by rows of "." (dead) and "O" (alive).
+type plaintextLoader struct{}
+
+func (plaintextLoader) sniff(lines []string) bool {
+	return len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "!")
+}
+
+func (plaintextLoader) load(lines []string) (map[string]*Cell, *Rule, error) {
+	cells := make(map[string]*Cell)
+
+	row := 0
+	for _, line := range lines {
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		for col, ch := range line {
+			if ch == 'O' {
+				cells[getCellKey(col, row)] = &Cell{x: col, y: row}
+			}
+		}
+		row++
+	}
+
+	return cells, nil, nil
+}
+
+// writeRLE serializes cellMap to Golly's RLE format.
+func writeRLE(w io.Writer, cellMap map[string]*Cell) error {
+	if len(cellMap) == 0 {
+		_, err := fmt.Fprintln(w, "x = 0, y = 0, rule = B3/S23")
+		return err
+	}
+
+	var minX, minY, maxX, maxY int
+	first := true
+	for _, cell := range cellMap {
+		if first {
+			minX, maxX = cell.x, cell.x
+			minY, maxY = cell.y, cell.y
+			first = false
+			continue
+		}
+
+		if cell.x < minX {
+			minX = cell.x
+		}
+		if cell.x > maxX {
+			maxX = cell.x
+		}
+		if cell.y < minY {
+			minY = cell.y
+		}
+		if cell.y > maxY {
+			maxY = cell.y
+		}
+	}
+
+	width := maxX - minX + 1
+	height := maxY - minY + 1
+
+	if _, err := fmt.Fprintf(w, "x = %d, y = %d, rule = B3/S23\n", width, height); err != nil {
+		return err
+	}
+
+	var body strings.Builder
+
+	for row := minY; row <= maxY; row++ {
+		runChar := byte(0)
+		runLen := 0
+
+		flush := func() {
+			if runLen == 0 {
+				return
+			}
+			if runLen > 1 {
+				body.WriteString(strconv.Itoa(runLen))
+			}
+			body.WriteByte(runChar)
+			runLen = 0
+		}
+
+		for col := minX; col <= maxX; col++ {
+			_, alive := cellMap[getCellKey(col, row)]
+			ch := byte('b')
+			if alive {
+				ch = 'o'
+			}
+
+			if ch == runChar {
+				runLen++
+			} else {
+				flush()
+				runChar = ch
+				runLen = 1
+			}
+		}
+		flush()
+
+		if row != maxY {
+			body.WriteByte('$')
+		}
+	}
+	body.WriteByte('!')
+
+	_, err := fmt.Fprintln(w, body.String())
+	return err
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadPatternRLERule checks that an RLE header's rule field is parsed
+// and surfaced to the caller instead of being silently discarded.
+func TestLoadPatternRLERule(t *testing.T) {
+	rle := "#N Glider\nx = 3, y = 3, rule = B36/S23\nbo$2bo$3o!\n"
+
+	cells, rule, err := loadPattern(strings.NewReader(rle))
+	if err != nil {
+		t.Fatalf("loadPattern: %v", err)
+	}
+
+	if len(cells) != 5 {
+		t.Fatalf("expected 5 live cells, got %d", len(cells))
+	}
+
+	if rule == nil {
+		t.Fatal("expected a rule parsed from the header, got nil")
+	}
+
+	if rule.Name != "B36/S23" {
+		t.Fatalf("expected rule B36/S23, got %s", rule.Name)
+	}
+}
+
+// TestLoadPatternRLENoRule checks that an RLE file whose header omits the
+// rule field reports no rule rather than a default-valued one, so callers
+// can tell "not specified" from "B3/S23".
+func TestLoadPatternRLENoRule(t *testing.T) {
+	rle := "x = 3, y = 3\nbo$2bo$3o!\n"
+
+	_, rule, err := loadPattern(strings.NewReader(rle))
+	if err != nil {
+		t.Fatalf("loadPattern: %v", err)
+	}
+
+	if rule != nil {
+		t.Fatalf("expected no rule, got %v", rule)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is a Golly-style B/S (birth/survive) rule, e.g. "B3/S23" for
+// Conway's Game of Life or "B36/S23" for HighLife. Birth[n]/Survive[n] is
+// true when a dead/live cell with n live neighbors should become alive.
+type Rule struct {
+	Birth   [9]bool
+	Survive [9]bool
+	Name    string
+}
+
+// defaultRule is Conway's B3/S23, used when no rule is supplied or when an
+// unrecognized rule string is encountered.
+var defaultRule = Rule{
+	Birth:   [9]bool{3: true},
+	Survive: [9]bool{2: true, 3: true},
+	Name:    "B3/S23",
+}
+
+// rulePresets are cycled through via hotkey for quick demos.
+var rulePresets = []Rule{
+	defaultRule,
+	mustParseRule("B36/S23"),       // HighLife
+	mustParseRule("B2/S"),          // Seeds
+	mustParseRule("B3/S012345678"), // Life without Death
+}
+
+func mustParseRule(rulestring string) Rule {
+	rule, err := parseRule(rulestring)
+	if err != nil {
+		panic(err)
+	}
+	return rule
+}
+
+// parseRule parses a Golly-style rulestring of the form "B<digits>/S<digits>".
+// An unrecognized or malformed rulestring falls back to B3/S23.
+func parseRule(rulestring string) (Rule, error) {
+	rulestring = strings.TrimSpace(rulestring)
+
+	parts := strings.SplitN(rulestring, "/", 2)
+	if len(parts) != 2 {
+		return defaultRule, fmt.Errorf("malformed rulestring %q, falling back to %s", rulestring, defaultRule.Name)
+	}
+
+	bPart := strings.TrimPrefix(strings.TrimSpace(parts[0]), "B")
+	sPart := strings.TrimPrefix(strings.TrimSpace(parts[1]), "S")
+
+	var rule Rule
+
+	for _, r := range bPart {
+		n, err := strconv.Atoi(string(r))
+		if err != nil || n > 8 {
+			return defaultRule, fmt.Errorf("malformed rulestring %q, falling back to %s", rulestring, defaultRule.Name)
+		}
+		rule.Birth[n] = true
+	}
+
+	for _, r := range sPart {
+		n, err := strconv.Atoi(string(r))
+		if err != nil || n > 8 {
+			return defaultRule, fmt.Errorf("malformed rulestring %q, falling back to %s", rulestring, defaultRule.Name)
+		}
+		rule.Survive[n] = true
+	}
+
+	rule.Name = "B" + bPart + "/S" + sPart
+
+	return rule, nil
+}